@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"io"
@@ -15,6 +14,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"disk-eaters/report"
+	"disk-eaters/reporter"
+	"disk-eaters/scanner"
+	"disk-eaters/snapshot"
 )
 
 // DiskEntry represents a file or directory with its size
@@ -33,34 +37,185 @@ type ProcessInfo struct {
 
 // Config holds the application configuration
 type Config struct {
-	ScanDir          string
-	LogDir           string
-	HistoryDir       string
-	CurrentSnapshot  string
-	PreviousSnapshot string
-	MaxItems         int
-	ShowProcesses    bool
+	ScanDir        string
+	LogDir         string
+	HistoryDir     string
+	MaxItems       int
+	ShowProcesses  bool
+	Workers        int
+	SameFilesystem bool
+	FollowSymlinks bool
+	Exclude        []string
+	Retain         time.Duration
+	RetainCount    int
+}
+
+// growthWindows are the historical intervals the report compares the
+// current snapshot against.
+var growthWindows = []struct {
+	label string
+	age   time.Duration
+}{
+	{"1d", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
 }
 
+// projectionWindow is the interval whose growth rate feeds the
+// days-to-capacity projection.
+const projectionWindow = 7 * 24 * time.Hour
+
 func main() {
 	// Parse command-line arguments
 	scanDir := flag.String("dir", "/", "Directory to scan")
 	logDir := flag.String("log", "/var/log/disk_eaters", "Log directory")
 	maxItems := flag.Int("max", 5, "Maximum number of items to show")
 	showProcesses := flag.Bool("processes", true, "Show processes using the files")
+	workers := flag.Int("workers", 0, "Concurrent directory workers (0 = number of CPUs)")
+	sameFilesystem := flag.Bool("xdev", true, "Don't cross filesystem boundaries while scanning")
+	followSymlinks := flag.Bool("follow-symlinks", false, "Follow symlinked directories while scanning")
+	exclude := flag.String("exclude", "/proc,/sys,/dev,/run", "Comma-separated paths to exclude from scanning")
+	watch := flag.Bool("watch", false, "Run continuously, re-scanning every -poll-period instead of once")
+	pollPeriod := flag.Duration("poll-period", 10*time.Minute, "Sampling interval in -watch mode")
+	watchPid := flag.Int("watch-pid", 0, "PID whose cgroup I/O and memory pressure to correlate with growth in -watch mode")
+	serve := flag.String("serve", "", "Address to serve /metrics (Prometheus) and /report.json on, e.g. :9310. Empty disables serving. Requires -watch, since a one-shot run has nothing left to serve once it exits")
+	retain := flag.String("retain", "90d", "Maximum age of a kept snapshot, e.g. 30d, 720h. 0 disables age-based retention")
+	retainCount := flag.Int("retain-count", 0, "Maximum number of snapshots to keep per kind, on top of -retain. 0 disables")
+	gc := flag.Bool("gc", false, "Prune snapshots exceeding the retention policy and exit, without scanning")
 	flag.Parse()
 
+	retainDuration, err := parseRetention(*retain)
+	if err != nil {
+		fmt.Printf("Error parsing -retain: %v\n", err)
+		return
+	}
+
 	// Create config
 	config := Config{
-		ScanDir:          *scanDir,
-		LogDir:           *logDir,
-		HistoryDir:       filepath.Join(*logDir, "history"),
-		CurrentSnapshot:  filepath.Join(*logDir, "current"),
-		PreviousSnapshot: filepath.Join(*logDir, "previous"),
-		MaxItems:         *maxItems,
-		ShowProcesses:    *showProcesses,
+		ScanDir:        *scanDir,
+		LogDir:         *logDir,
+		HistoryDir:     filepath.Join(*logDir, "history"),
+		MaxItems:       *maxItems,
+		ShowProcesses:  *showProcesses,
+		Workers:        *workers,
+		SameFilesystem: *sameFilesystem,
+		FollowSymlinks: *followSymlinks,
+		Exclude:        strings.Split(*exclude, ","),
+		Retain:         retainDuration,
+		RetainCount:    *retainCount,
+	}
+
+	if *gc {
+		runGC(config)
+		return
+	}
+
+	if *serve != "" && !*watch {
+		fmt.Println("Error: -serve requires -watch; a one-shot run exits before anything can scrape it")
+		return
+	}
+
+	if *serve != "" {
+		srv, err := report.Serve(*serve, getLatestReport)
+		if err != nil {
+			fmt.Printf("Error starting -serve listener on %s: %v\n", *serve, err)
+			return
+		}
+		defer srv.Close()
 	}
 
+	if *watch {
+		runWatch(config, *pollPeriod, *watchPid)
+		return
+	}
+
+	if err := runOnce(config); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// parseRetention parses a retention duration, additionally accepting a
+// "<n>d" day suffix since time.ParseDuration doesn't support one.
+func parseRetention(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid day count %q: %w", s, err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// runGC prunes snapshots exceeding config's retention policy for both
+// snapshot kinds and reports how many were removed.
+func runGC(config Config) {
+	store := snapshot.NewStore(config.HistoryDir, config.Retain, config.RetainCount)
+	now := time.Now()
+	for _, kind := range []string{"dirs", "files"} {
+		removed, err := store.GC(kind, now)
+		if err != nil {
+			fmt.Printf("Error garbage-collecting %s snapshots: %v\n", kind, err)
+			continue
+		}
+		fmt.Printf("Removed %d expired %s snapshot(s)\n", removed, kind)
+	}
+}
+
+// latestReport holds the most recently completed Report so report.Serve
+// can render it on demand without recomputing anything.
+var (
+	latestReportMu sync.Mutex
+	latestReport   *report.Report
+)
+
+func setLatestReport(r *report.Report) {
+	latestReportMu.Lock()
+	defer latestReportMu.Unlock()
+	latestReport = r
+}
+
+func getLatestReport() *report.Report {
+	latestReportMu.Lock()
+	defer latestReportMu.Unlock()
+	return latestReport
+}
+
+// runWatch runs runOnce on every tick of pollPeriod, sharing the exact
+// same scan/snapshot/growth code path as the one-shot mode, and in
+// parallel runs a reporter.Reporter that correlates disk growth with the
+// watched process's cgroup I/O and memory pressure.
+func runWatch(config Config, pollPeriod time.Duration, watchPid int) {
+	rep := &reporter.Reporter{
+		Paths:      []string{config.ScanDir},
+		TempDir:    config.LogDir,
+		PollPeriod: pollPeriod,
+		Thresholds: map[string][]int64{
+			config.ScanDir + ":free-bytes":        {1 << 30}, // alert below 1 GiB free
+			config.ScanDir + ":inode-percent":     {90},      // alert above 90% inodes used
+			config.ScanDir + ":growth-per-minute": {100 << 20},
+		},
+		Logger:          os.Stdout,
+		ThresholdLogger: os.Stdout,
+	}
+	if watchPid > 0 {
+		rep.Pid = func() int { return watchPid }
+	}
+	rep.Start()
+	defer rep.Stop()
+
+	for {
+		if err := runOnce(config); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		time.Sleep(pollPeriod)
+	}
+}
+
+// runOnce performs a single scan, prints the report, and archives the
+// snapshot for the next run's growth comparison. This is the body used by
+// both the default one-shot mode and each tick of -watch mode.
+func runOnce(config Config) error {
 	// Ensure log directories exist
 	os.MkdirAll(config.LogDir, 0755)
 	os.MkdirAll(config.HistoryDir, 0755)
@@ -70,291 +225,196 @@ func main() {
 	resultFile := filepath.Join(config.HistoryDir, fmt.Sprintf("disk_eaters_%s.log", date))
 	f, err := os.Create(resultFile)
 	if err != nil {
-		fmt.Printf("Error creating result file: %v\n", err)
-		return
+		return fmt.Errorf("creating result file: %w", err)
 	}
 	defer f.Close()
 
 	// Create a multiwriter to write to both file and stdout
 	mw := io.MultiWriter(os.Stdout, f)
 
-	// Write header
-	fmt.Fprintf(mw, "DISK EATERS WATCH REPORT - %s\n", date)
-	fmt.Fprintf(mw, "Scan Directory: %s\n\n", config.ScanDir)
+	// Scan once and derive both the directory and file views from the
+	// same pass, rather than walking the tree twice.
+	scanResult, scanErr := scanner.Scan(config.ScanDir, scanner.ScanOptions{
+		Workers:        config.Workers,
+		Exclude:        config.Exclude,
+		SameFilesystem: config.SameFilesystem,
+		FollowSymlinks: config.FollowSymlinks,
+		TopFiles:       config.MaxItems,
+	})
+	if scanErr != nil {
+		return fmt.Errorf("scanning %s: %w", config.ScanDir, scanErr)
+	}
 
-	// Find largest directories
-	printHeader(mw, fmt.Sprintf("TOP %d LARGEST DIRECTORIES UNDER %s", config.MaxItems, config.ScanDir))
-	dirs, err := findLargestDirectories(config.ScanDir, config.MaxItems)
-	if err != nil {
-		fmt.Fprintf(mw, "Error finding directories: %v\n", err)
-	} else {
-		for _, dir := range dirs {
-			fmt.Fprintf(mw, "%s\t%s\n", formatSize(dir.Size), dir.Path)
-		}
-		// Save current directories data
-		saveEntries(dirs, config.CurrentSnapshot+".dirs")
+	dirs := topDirs(scanResult, config.MaxItems)
+	files := topFiles(scanResult, config.MaxItems)
+
+	store := snapshot.NewStore(config.HistoryDir, config.Retain, config.RetainCount)
+	now := time.Now()
+	if err := store.Save("dirs", now, toSnapshotEntries(dirs)); err != nil {
+		fmt.Fprintf(mw, "Error saving dirs snapshot: %v\n", err)
+	}
+	if err := store.Save("files", now, toSnapshotEntries(files)); err != nil {
+		fmt.Fprintf(mw, "Error saving files snapshot: %v\n", err)
+	}
+	if removed, err := store.GC("dirs", now); err != nil {
+		fmt.Fprintf(mw, "Error garbage-collecting dirs snapshots: %v\n", err)
+	} else if removed > 0 {
+		fmt.Fprintf(mw, "Pruned %d expired dirs snapshot(s)\n", removed)
+	}
+	if removed, err := store.GC("files", now); err != nil {
+		fmt.Fprintf(mw, "Error garbage-collecting files snapshots: %v\n", err)
+	} else if removed > 0 {
+		fmt.Fprintf(mw, "Pruned %d expired files snapshot(s)\n", removed)
 	}
-	fmt.Fprintln(mw, "")
 
-	// Find largest files
-	printHeader(mw, fmt.Sprintf("TOP %d LARGEST FILES UNDER %s", config.MaxItems, config.ScanDir))
-	files, err := findLargestFiles(config.ScanDir, config.MaxItems)
-	if err != nil {
-		fmt.Fprintf(mw, "Error finding files: %v\n", err)
-	} else {
-		for _, file := range files {
-			fmt.Fprintf(mw, "%s\t%s\n", formatSize(file.Size), file.Path)
+	var growth []report.GrowthWindow
+	for _, gw := range growthWindows {
+		dirGrowth, err := store.GrowthOver("dirs", now, gw.age, config.MaxItems)
+		if err != nil {
+			fmt.Fprintf(mw, "Error computing %s dir growth: %v\n", gw.label, err)
+		}
+		fileGrowth, err := store.GrowthOver("files", now, gw.age, config.MaxItems)
+		if err != nil {
+			fmt.Fprintf(mw, "Error computing %s file growth: %v\n", gw.label, err)
 		}
+		growth = append(growth, report.GrowthWindow{
+			Label: gw.label,
+			Dirs:  reportEntries(dirGrowth),
+			Files: reportEntries(fileGrowth),
+		})
+	}
 
-		// Show processes using these files if requested
-		if config.ShowProcesses && len(files) > 0 {
-			fmt.Fprintln(mw, "")
-			printHeader(mw, "PROCESSES USING LARGE FILES")
-			
+	projections := buildProjections(store, config.ScanDir, dirs, now)
+
+	var processes []report.ProcessUse
+	if config.ShowProcesses {
+		processes = []report.ProcessUse{}
+		index, err := newProcessIndex()
+		if err != nil {
+			fmt.Fprintf(mw, "Error building process index: %v\n", err)
+		} else {
 			for _, file := range files {
-				fmt.Fprintf(mw, "\nFile: %s (%s)\n", file.Path, formatSize(file.Size))
-				
-				processes, err := findProcessesUsingFile(file.Path)
+				procs, err := findProcessesUsingFile(file.Path, index)
 				if err != nil {
-					fmt.Fprintf(mw, "  Error finding processes: %v\n", err)
 					continue
 				}
-				
-				if len(processes) == 0 {
-					fmt.Fprintf(mw, "  No processes currently using this file\n")
-				} else {
-					fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", "PID", "USER", "ACCESS", "COMMAND")
-					fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", "---", "----", "------", "-------")
-					for _, proc := range processes {
-						fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", proc.PID, proc.User, proc.Access, proc.Command)
-					}
+				for _, p := range procs {
+					processes = append(processes, report.ProcessUse{
+						File: file.Path, PID: p.PID, User: p.User, Command: p.Command, Access: p.Access,
+					})
 				}
 			}
 		}
-		
-		// Save current files data
-		saveEntries(files, config.CurrentSnapshot+".files")
 	}
-	fmt.Fprintln(mw, "")
 
-	// Analyze growth if previous data exists
-	printHeader(mw, fmt.Sprintf("TOP %d FASTEST GROWING DIRECTORIES UNDER %s", config.MaxItems, config.ScanDir))
-	if _, err := os.Stat(config.PreviousSnapshot + ".dirs"); err == nil {
-		dirGrowth, err := analyzeGrowth(config.CurrentSnapshot+".dirs", config.PreviousSnapshot+".dirs", config.MaxItems)
-		if err != nil {
-			fmt.Fprintf(mw, "Error analyzing directory growth: %v\n", err)
-		} else {
-			for _, growth := range dirGrowth {
-				fmt.Fprintf(mw, "%s\t%s\n", formatSize(growth.Size), growth.Path)
-			}
-		}
-	} else {
-		fmt.Fprintln(mw, "No previous data available for comparison. Growth analysis will be available after the next run.")
+	rep := &report.Report{
+		Timestamp:   now,
+		ScanRoot:    config.ScanDir,
+		TopDirs:     toEntries(dirs),
+		TopFiles:    toEntries(files),
+		Growth:      growth,
+		Projections: projections,
+		Processes:   processes,
 	}
-	fmt.Fprintln(mw, "")
+	setLatestReport(rep)
 
-	printHeader(mw, fmt.Sprintf("TOP %d FASTEST GROWING FILES UNDER %s", config.MaxItems, config.ScanDir))
-	if _, err := os.Stat(config.PreviousSnapshot + ".files"); err == nil {
-		fileGrowth, err := analyzeGrowth(config.CurrentSnapshot+".files", config.PreviousSnapshot+".files", config.MaxItems)
-		if err != nil {
-			fmt.Fprintf(mw, "Error analyzing file growth: %v\n", err)
-		} else {
-			for _, growth := range fileGrowth {
-				fmt.Fprintf(mw, "%s\t%s\n", formatSize(growth.Size), growth.Path)
-			}
-			
-			// Show processes using these growing files if requested
-			if config.ShowProcesses && len(fileGrowth) > 0 {
-				fmt.Fprintln(mw, "")
-				printHeader(mw, "PROCESSES USING FAST-GROWING FILES")
-				
-				for _, file := range fileGrowth {
-					fmt.Fprintf(mw, "\nFile: %s (grew by %s)\n", file.Path, formatSize(file.Size))
-					
-					processes, err := findProcessesUsingFile(file.Path)
-					if err != nil {
-						fmt.Fprintf(mw, "  Error finding processes: %v\n", err)
-						continue
-					}
-					
-					if len(processes) == 0 {
-						fmt.Fprintf(mw, "  No processes currently using this file\n")
-					} else {
-						fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", "PID", "USER", "ACCESS", "COMMAND")
-						fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", "---", "----", "------", "-------")
-						for _, proc := range processes {
-							fmt.Fprintf(mw, "  %-8s %-10s %-8s %s\n", proc.PID, proc.User, proc.Access, proc.Command)
-						}
-					}
-				}
-			}
-		}
-	} else {
-		fmt.Fprintln(mw, "No previous data available for comparison. Growth analysis will be available after the next run.")
-	}
-	fmt.Fprintln(mw, "")
-
-	// Archive current data for next run's comparison
-	if _, err := os.Stat(config.CurrentSnapshot + ".dirs"); err == nil {
-		copyFile(config.CurrentSnapshot+".dirs", config.PreviousSnapshot+".dirs")
-	}
-	if _, err := os.Stat(config.CurrentSnapshot + ".files"); err == nil {
-		copyFile(config.CurrentSnapshot+".files", config.PreviousSnapshot+".files")
-	}
-
-	// Print summary
-	printHeader(mw, "SUMMARY")
-	fmt.Fprintf(mw, "Log saved to: %s\n", resultFile)
-	fmt.Fprintln(mw, "Run this program daily to track growth patterns.")
-	fmt.Fprintln(mw, "")
-
-	// Add cron setup instructions
-	fmt.Fprintln(mw, "--------------------------------------------------------")
-	fmt.Fprintln(mw, "CRON SETUP INSTRUCTIONS")
-	fmt.Fprintln(mw, "--------------------------------------------------------")
-	fmt.Fprintln(mw, "To run this program daily via cron, execute:")
-	fmt.Fprintln(mw, "")
-	fmt.Fprintln(mw, "sudo crontab -e")
-	fmt.Fprintln(mw, "")
-	fmt.Fprintln(mw, "Then add the following line:")
-	fmt.Fprintln(mw, "")
-	fmt.Fprintln(mw, "# Run disk eaters watch program daily at 2 AM")
-	fmt.Fprintln(mw, "0 2 * * * /path/to/disk_eaters -dir / > /dev/null 2>&1")
-	fmt.Fprintln(mw, "")
-	fmt.Fprintln(mw, "Replace \"/path/to/\" with the actual path where you saved this program.")
-	fmt.Fprintln(mw, "Replace \"/\" with the directory you want to scan if not the root.")
-}
+	if err := report.WriteText(mw, rep); err != nil {
+		return fmt.Errorf("writing text report: %w", err)
+	}
+	printSummary(mw, resultFile)
 
-// findProcessesUsingFile finds all processes using a file
-func findProcessesUsingFile(filePath string) ([]ProcessInfo, error) {
-	var processes []ProcessInfo
-	
-	// Different implementations for different operating systems
-	switch runtime.GOOS {
-	case "linux":
-		return findProcessesLinux(filePath)
-	case "darwin":
-		return findProcessesMacOS(filePath)
-	case "windows":
-		return findProcessesWindows(filePath)
-	default:
-		return nil, fmt.Errorf("process finding not implemented for %s", runtime.GOOS)
+	if err := appendJSONReport(config.LogDir, rep); err != nil {
+		fmt.Fprintf(mw, "Error appending JSON report: %v\n", err)
 	}
 
-	return processes, nil
+	return nil
 }
 
-// findProcessesLinux finds processes using a file on Linux using lsof
-func findProcessesLinux(filePath string) ([]ProcessInfo, error) {
-	var processes []ProcessInfo
+// buildProjections estimates, for each of the top directories, how many
+// days its current growth rate (over projectionWindow) would take to
+// exhaust the free space on the scanned filesystem.
+func buildProjections(store *snapshot.Store, scanDir string, dirs []DiskEntry, now time.Time) []report.Projection {
+	freeBytes, err := reporter.FreeBytes(scanDir)
+	if err != nil {
+		return nil
+	}
 
-	// Try to use lsof first
-	cmd := exec.Command("lsof", "-F", "pcun", filePath)
-	output, err := cmd.Output()
-	if err == nil {
-		// Parse lsof output
-		return parseLsofOutput(string(output)), nil
-	}
-	
-	// Fall back to fuser if lsof fails
-	cmd = exec.Command("fuser", "-v", filePath)
-	output, err = cmd.Output()
-	if err == nil {
-		lines := strings.Split(string(output), "\n")
-		if len(lines) >= 2 {
-			// Skip the header line and process the rest
-			for i := 1; i < len(lines); i++ {
-				if lines[i] == "" {
-					continue
-				}
-				
-				fields := strings.Fields(lines[i])
-				if len(fields) >= 3 {
-					pid := fields[0]
-					user := fields[1]
-					access := "?"
-					command := strings.Join(fields[2:], " ")
-					
-					processes = append(processes, ProcessInfo{
-						PID:     pid,
-						User:    user,
-						Command: command,
-						Access:  access,
-					})
-				}
-			}
+	var projections []report.Projection
+	for _, d := range dirs {
+		rate, ok := store.GrowthRate("dirs", d.Path, now, projectionWindow)
+		if !ok || rate <= 0 {
+			continue
 		}
-		return processes, nil
+		projections = append(projections, report.Projection{
+			Path:           d.Path,
+			Window:         "7d",
+			BytesPerDay:    rate,
+			DaysToCapacity: float64(freeBytes) / rate,
+		})
 	}
-	
-	// If both fail, try to use /proc directly (Linux-specific)
-	files, err := filepath.Glob("/proc/[0-9]*/fd/*")
-	if err != nil {
-		return nil, err
+
+	sort.Slice(projections, func(i, j int) bool {
+		return projections[i].DaysToCapacity < projections[j].DaysToCapacity
+	})
+	return projections
+}
+
+// toEntries converts the snapshot-persistence DiskEntry type to the
+// report package's Entry type.
+func toEntries(entries []DiskEntry) []report.Entry {
+	out := make([]report.Entry, len(entries))
+	for i, e := range entries {
+		out[i] = report.Entry{Path: e.Path, Size: e.Size}
+	}
+	return out
+}
+
+// toSnapshotEntries converts DiskEntry to the snapshot package's Entry
+// type, for handing a scan's results to a snapshot.Store.
+func toSnapshotEntries(entries []DiskEntry) []snapshot.Entry {
+	out := make([]snapshot.Entry, len(entries))
+	for i, e := range entries {
+		out[i] = snapshot.Entry{Path: e.Path, Size: e.Size}
+	}
+	return out
+}
+
+// reportEntries converts the snapshot package's Entry type, as returned
+// by a snapshot.Store growth query, directly to report.Entry.
+func reportEntries(entries []snapshot.Entry) []report.Entry {
+	out := make([]report.Entry, len(entries))
+	for i, e := range entries {
+		out[i] = report.Entry{Path: e.Path, Size: e.Size}
 	}
-	
-	absPath, err := filepath.Abs(filePath)
+	return out
+}
+
+// appendJSONReport appends r as one newline-delimited JSON record to
+// <logDir>/disk_eaters.ndjson, so growth trends can be scraped or
+// replayed without re-parsing the human-readable log.
+func appendJSONReport(logDir string, r *report.Report) error {
+	f, err := os.OpenFile(filepath.Join(logDir, "disk_eaters.ndjson"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	
-	for _, fdPath := range files {
-		target, err := os.Readlink(fdPath)
-		if err == nil && target == absPath {
-			// Extract PID from path
-			parts := strings.Split(fdPath, "/")
-			if len(parts) >= 3 {
-				pid := parts[2]
-				
-				// Get user and command
-				cmdlinePath := filepath.Join("/proc", pid, "cmdline")
-				cmdline, err := os.ReadFile(cmdlinePath)
-				if err != nil {
-					continue
-				}
-				
-				// Replace null bytes with spaces
-				command := strings.ReplaceAll(string(cmdline), "\x00", " ")
-				
-				// Get user if possible
-				statusPath := filepath.Join("/proc", pid, "status")
-				statusContent, err := os.ReadFile(statusPath)
-				if err != nil {
-					continue
-				}
-				
-				user := "?"
-				for _, line := range strings.Split(string(statusContent), "\n") {
-					if strings.HasPrefix(line, "Uid:") {
-						fields := strings.Fields(line)
-						if len(fields) >= 2 {
-							user = fields[1]
-							break
-						}
-					}
-				}
-				
-				// Check file access mode
-				var access string
-				if strings.Contains(fdPath, "r") {
-					access = "r"
-				} else if strings.Contains(fdPath, "w") {
-					access = "w"
-				} else {
-					access = "?"
-				}
-				
-				processes = append(processes, ProcessInfo{
-					PID:     pid,
-					User:    user,
-					Command: command,
-					Access:  access,
-				})
-			}
-		}
+	defer f.Close()
+	return report.WriteJSON(f, r)
+}
+
+// findProcessesUsingFile finds all processes using a file. On Linux it
+// looks filePath up in a process index built once per scan; other
+// platforms fork+exec into lsof or handle.exe per file.
+func findProcessesUsingFile(filePath string, index processIndex) ([]ProcessInfo, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return index.find(filePath)
+	case "darwin":
+		return findProcessesMacOS(filePath)
+	case "windows":
+		return findProcessesWindows(filePath)
+	default:
+		return nil, fmt.Errorf("process finding not implemented for %s", runtime.GOOS)
 	}
-	
-	return processes, nil
 }
 
 // findProcessesMacOS finds processes using a file on macOS
@@ -365,7 +425,7 @@ func findProcessesMacOS(filePath string) ([]ProcessInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	
+
 	return parseLsofOutput(string(output)), nil
 }
 
@@ -377,7 +437,7 @@ func findProcessesWindows(filePath string) ([]ProcessInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("process finding on Windows requires Sysinternals Handle tool")
 	}
-	
+
 	processes := []ProcessInfo{}
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
@@ -387,7 +447,7 @@ func findProcessesWindows(filePath string) ([]ProcessInfo, error) {
 				pidMatch := regexp.MustCompile(`pid: (\d+)`).FindStringSubmatch(line)
 				if len(pidMatch) >= 2 {
 					pid := pidMatch[1]
-					
+
 					// Try to get more info about this process
 					cmdProc := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %s", pid), "/FO", "CSV")
 					procOutput, err := cmdProc.Output()
@@ -398,7 +458,7 @@ func findProcessesWindows(filePath string) ([]ProcessInfo, error) {
 							csvFields := strings.Split(procLines[1], ",")
 							if len(csvFields) >= 2 {
 								command := strings.Trim(csvFields[0], "\"")
-								
+
 								processes = append(processes, ProcessInfo{
 									PID:     pid,
 									User:    "N/A", // Windows doesn't easily show this in tasklist
@@ -412,7 +472,7 @@ func findProcessesWindows(filePath string) ([]ProcessInfo, error) {
 			}
 		}
 	}
-	
+
 	return processes, nil
 }
 
@@ -420,17 +480,17 @@ func findProcessesWindows(filePath string) ([]ProcessInfo, error) {
 func parseLsofOutput(output string) []ProcessInfo {
 	var processes []ProcessInfo
 	var currentProcess ProcessInfo
-	
+
 	// lsof -F output format has one character field identifiers
 	// p: PID, c: command, u: user, n: filename
 	for _, line := range strings.Split(output, "\n") {
 		if line == "" {
 			continue
 		}
-		
+
 		fieldType := line[0]
 		value := line[1:]
-		
+
 		switch fieldType {
 		case 'p':
 			// Start of a new process
@@ -453,292 +513,63 @@ func parseLsofOutput(output string) []ProcessInfo {
 			}
 		}
 	}
-	
+
 	// Add the last process if any
 	if currentProcess.PID != "" {
 		processes = append(processes, currentProcess)
 	}
-	
-	return processes
-}
-
-// printHeader prints a formatted header to the given writer
-func printHeader(w io.Writer, header string) {
-	fmt.Fprintln(w, "==================================================")
-	fmt.Fprintf(w, "  %s\n", header)
-	fmt.Fprintln(w, "==================================================")
-}
-
-// formatSize converts size in bytes to human-readable format
-func formatSize(sizeInBytes int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-		TB = GB * 1024
-	)
-
-	switch {
-	case sizeInBytes >= TB:
-		return fmt.Sprintf("%.2f TB", float64(sizeInBytes)/float64(TB))
-	case sizeInBytes >= GB:
-		return fmt.Sprintf("%.2f GB", float64(sizeInBytes)/float64(GB))
-	case sizeInBytes >= MB:
-		return fmt.Sprintf("%.2f MB", float64(sizeInBytes)/float64(MB))
-	case sizeInBytes >= KB:
-		return fmt.Sprintf("%.2f KB", float64(sizeInBytes)/float64(KB))
-	default:
-		return fmt.Sprintf("%d B", sizeInBytes)
-	}
-}
-
-// findLargestDirectories finds the largest directories in the given path
-func findLargestDirectories(rootPath string, maxItems int) ([]DiskEntry, error) {
-	var allDirs []DiskEntry
-	var mutex sync.Mutex
-	var wg sync.WaitGroup
-
-	// Process a directory and its immediate subdirectories
-	var processDir func(path string, depth int)
-	processDir = func(path string, depth int) {
-		defer wg.Done()
-
-		// Don't go too deep
-		if depth > 4 {
-			return
-		}
-
-		var dirSize int64
-		var subDirs []string
-
-		// Walk the directory
-		filepath.Walk(path, func(subPath string, info os.FileInfo, err error) error {
-			// Skip errors
-			if err != nil {
-				return filepath.SkipDir
-			}
-
-			// Skip different filesystems
-			if subPath != path && info.IsDir() && isOnDifferentFilesystem(path, subPath) {
-				return filepath.SkipDir
-			}
-
-			// Add file sizes
-			if !info.IsDir() {
-				dirSize += info.Size()
-				return nil
-			}
-
-			// Skip processing current dir
-			if subPath == path {
-				return nil
-			}
-
-			// Collect subdirectories for concurrent processing
-			if depth < 4 && filepath.Dir(subPath) == path {
-				subDirs = append(subDirs, subPath)
-				return filepath.SkipDir // Skip further traversal of this subdir for now
-			}
-
-			return nil
-		})
-
-		// Add this directory to our list
-		mutex.Lock()
-		allDirs = append(allDirs, DiskEntry{Path: path, Size: dirSize})
-		mutex.Unlock()
-
-		// Process subdirectories concurrently
-		for _, subDir := range subDirs {
-			wg.Add(1)
-			go processDir(subDir, depth+1)
-		}
-	}
-
-	// Start processing the root directory
-	wg.Add(1)
-	processDir(rootPath, 0)
-	wg.Wait()
-
-	// Sort by size (largest first)
-	sort.Slice(allDirs, func(i, j int) bool {
-		return allDirs[i].Size > allDirs[j].Size
-	})
-
-	// Return top N
-	if len(allDirs) > maxItems {
-		return allDirs[:maxItems], nil
-	}
-	return allDirs, nil
-}
-
-// findLargestFiles finds the largest files in the given path
-func findLargestFiles(rootPath string, maxItems int) ([]DiskEntry, error) {
-	var allFiles []DiskEntry
-	var mutex sync.Mutex
-
-	// Walk all files
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		// Skip errors
-		if err != nil {
-			return nil
-		}
-
-		// Skip directories and symbolic links
-		if info.IsDir() {
-			if isOnDifferentFilesystem(rootPath, path) && path != rootPath {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if !info.Mode().IsRegular() {
-			return nil
-		}
-
-		mutex.Lock()
-		allFiles = append(allFiles, DiskEntry{Path: path, Size: info.Size()})
-		mutex.Unlock()
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Sort by size (largest first)
-	sort.Slice(allFiles, func(i, j int) bool {
-		return allFiles[i].Size > allFiles[j].Size
-	})
-
-	// Return top N
-	if len(allFiles) > maxItems {
-		return allFiles[:maxItems], nil
-	}
-	return allFiles, nil
-}
-
-// isOnDifferentFilesystem checks if two paths are on different filesystems
-func isOnDifferentFilesystem(path1, path2 string) bool {
-	stat1, err1 := os.Stat(path1)
-	stat2, err2 := os.Stat(path2)
-	if err1 != nil || err2 != nil {
-		return false
-	}
-
-	return stat1.Sys() != stat2.Sys()
-}
-
-// saveEntries saves disk entries to a file
-func saveEntries(entries []DiskEntry, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	for _, entry := range entries {
-		fmt.Fprintf(writer, "%d\t%s\n", entry.Size, entry.Path)
-	}
-	return writer.Flush()
+	return processes
 }
 
-// analyzeGrowth compares current and previous data to find growth
-func analyzeGrowth(currentFile, previousFile string, maxItems int) ([]DiskEntry, error) {
-	// Load current entries
-	current, err := loadEntries(currentFile)
-	if err != nil {
-		return nil, err
-	}
-
-	// Load previous entries
-	previous, err := loadEntries(previousFile)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create map for previous entries
-	prevMap := make(map[string]int64)
-	for _, entry := range previous {
-		prevMap[entry.Path] = entry.Size
-	}
-
-	// Calculate growth
-	var growthEntries []DiskEntry
-	for _, entry := range current {
-		if prevSize, exists := prevMap[entry.Path]; exists {
-			growth := entry.Size - prevSize
-			if growth > 0 {
-				growthEntries = append(growthEntries, DiskEntry{
-					Path: entry.Path,
-					Size: growth,
-				})
-			}
-		}
-	}
-
-	// Sort by growth (largest first)
-	sort.Slice(growthEntries, func(i, j int) bool {
-		return growthEntries[i].Size > growthEntries[j].Size
-	})
-
-	// Return top N
-	if len(growthEntries) > maxItems {
-		return growthEntries[:maxItems], nil
-	}
-	return growthEntries, nil
+// printSummary prints the closing summary and cron setup instructions
+// that follow every report, one-shot or -watch.
+func printSummary(w io.Writer, resultFile string) {
+	report.PrintHeader(w, "SUMMARY")
+	fmt.Fprintf(w, "Log saved to: %s\n", resultFile)
+	fmt.Fprintln(w, "Run this program daily to track growth patterns.")
+	fmt.Fprintln(w, "")
+
+	fmt.Fprintln(w, "--------------------------------------------------------")
+	fmt.Fprintln(w, "CRON SETUP INSTRUCTIONS")
+	fmt.Fprintln(w, "--------------------------------------------------------")
+	fmt.Fprintln(w, "To run this program daily via cron, execute:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "sudo crontab -e")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Then add the following line:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "# Run disk eaters watch program daily at 2 AM")
+	fmt.Fprintln(w, "0 2 * * * /path/to/disk_eaters -dir / > /dev/null 2>&1")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Replace \"/path/to/\" with the actual path where you saved this program.")
+	fmt.Fprintln(w, "Replace \"/\" with the directory you want to scan if not the root.")
 }
 
-// loadEntries loads disk entries from a file
-func loadEntries(filename string) ([]DiskEntry, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// topDirs returns the maxItems largest directories from a scanner.Scan
+// result as DiskEntry, the type the rest of main deals in.
+func topDirs(result *scanner.ScanResult, maxItems int) []DiskEntry {
+	dirs := result.Dirs
+	if len(dirs) > maxItems {
+		dirs = dirs[:maxItems]
 	}
-	defer file.Close()
-
-	var entries []DiskEntry
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		size, err := strconv.ParseInt(parts[0], 10, 64)
-		if err != nil {
-			continue
-		}
-
-		entries = append(entries, DiskEntry{
-			Path: parts[1],
-			Size: size,
-		})
+	entries := make([]DiskEntry, len(dirs))
+	for i, d := range dirs {
+		entries[i] = DiskEntry{Path: d.Path, Size: d.Size}
 	}
-
-	return entries, scanner.Err()
+	return entries
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer in.Close()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
+// topFiles returns the maxItems largest files from a scanner.Scan result
+// as DiskEntry, the type the rest of main deals in.
+func topFiles(result *scanner.ScanResult, maxItems int) []DiskEntry {
+	files := result.Files
+	if len(files) > maxItems {
+		files = files[:maxItems]
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	if err != nil {
-		return err
+	entries := make([]DiskEntry, len(files))
+	for i, f := range files {
+		entries[i] = DiskEntry{Path: f.Path, Size: f.Size}
 	}
-	return out.Sync()
+	return entries
 }
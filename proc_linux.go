@@ -0,0 +1,175 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// fileKey identifies a file by device and inode, the same identity the
+// kernel uses, so it is stable across the bind mounts and hardlinks that
+// make path comparison unreliable.
+type fileKey struct {
+	dev   uint64
+	inode uint64
+}
+
+// processIndex is a (dev, inode) -> []ProcessInfo index built once per
+// scan from every process's open file descriptors, so looking up each of
+// a scan's top files costs one map read instead of a fresh /proc walk.
+type processIndex map[fileKey][]ProcessInfo
+
+// newProcessIndex builds the index for the current process table. This is
+// O(fds) once per scan rather than O(files) fork+exec calls into lsof or
+// fuser, or O(files x fds) /proc walks.
+func newProcessIndex() (processIndex, error) {
+	return buildFDIndex()
+}
+
+// find looks filePath's (dev, inode) up in the index.
+func (idx processIndex) find(filePath string) ([]ProcessInfo, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(filePath, &st); err != nil {
+		return nil, err
+	}
+	return idx[fileKey{dev: uint64(st.Dev), inode: st.Ino}], nil
+}
+
+// buildFDIndex walks /proc/<pid>/fd for every running process once and
+// returns a map from the (dev, inode) of each open file to the processes
+// holding it open.
+func buildFDIndex() (map[fileKey][]ProcessInfo, error) {
+	index := make(map[fileKey][]ProcessInfo)
+
+	pidDirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pidDir := range pidDirs {
+		pid, err := strconv.Atoi(pidDir.Name())
+		if err != nil {
+			continue // not a PID directory
+		}
+
+		fdDir := filepath.Join("/proc", pidDir.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // process exited mid-scan, or fds unreadable without privilege
+		}
+
+		var base *ProcessInfo // resolved lazily, shared across this pid's fds
+		for _, fd := range fds {
+			var st syscall.Stat_t
+			if err := syscall.Stat(filepath.Join(fdDir, fd.Name()), &st); err != nil {
+				continue
+			}
+
+			if base == nil {
+				info, err := processInfo(pid)
+				if err != nil {
+					break // process gone; skip its remaining fds too
+				}
+				base = &info
+			}
+
+			proc := *base
+			proc.Access = fdAccessMode(pid, fd.Name())
+
+			key := fileKey{dev: uint64(st.Dev), inode: st.Ino}
+			index[key] = append(index[key], proc)
+		}
+	}
+
+	return index, nil
+}
+
+// processInfo resolves the user and command line for pid.
+func processInfo(pid int) (ProcessInfo, error) {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	command := strings.ReplaceAll(strings.TrimRight(string(cmdline), "\x00"), "\x00", " ")
+
+	uid, err := processUid(pid)
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	username := uid
+	if u, err := user.LookupId(uid); err == nil {
+		username = u.Username
+	}
+
+	return ProcessInfo{
+		PID:     strconv.Itoa(pid),
+		User:    username,
+		Command: command,
+	}, nil
+}
+
+// processUid reads the real UID of pid from the Uid: line of
+// /proc/<pid>/status.
+func processUid(pid int) (string, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("no Uid line in /proc/%d/status", pid)
+}
+
+// fdAccessMode reads the real access mode of fd from
+// /proc/<pid>/fdinfo/<fd>, parsing the flags: line (the octal open(2)
+// flags) and masking against O_ACCMODE. The old implementation guessed
+// from characters in the /proc/<pid>/fd/<n> path, which always contains
+// both "r" and "w" and so never worked.
+func fdAccessMode(pid int, fd string) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%s", pid, fd))
+	if err != nil {
+		return "?"
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "flags:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			break
+		}
+		flags, err := strconv.ParseInt(fields[1], 8, 64)
+		if err != nil {
+			break
+		}
+		switch int(flags) & syscall.O_ACCMODE {
+		case syscall.O_RDONLY:
+			return "r"
+		case syscall.O_WRONLY:
+			return "w"
+		case syscall.O_RDWR:
+			return "rw"
+		}
+		break
+	}
+	return "?"
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+// processIndex backs findProcessesUsingFile's runtime.GOOS switch on linux
+// only; it is unreachable on other platforms, which use findProcessesMacOS
+// or findProcessesWindows instead.
+type processIndex struct{}
+
+func newProcessIndex() (processIndex, error) {
+	return processIndex{}, nil
+}
+
+func (processIndex) find(filePath string) ([]ProcessInfo, error) {
+	return nil, fmt.Errorf("process index is only supported on linux")
+}
@@ -0,0 +1,13 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteJSON renders r as a single line of JSON followed by a newline, so
+// repeated calls against the same writer produce newline-delimited JSON.
+func WriteJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	return enc.Encode(r)
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WritePrometheus renders r as Prometheus text-format gauges.
+func WritePrometheus(w io.Writer, r *Report) error {
+	fmt.Fprintln(w, "# HELP disk_eaters_dir_bytes Recursive size of a directory.")
+	fmt.Fprintln(w, "# TYPE disk_eaters_dir_bytes gauge")
+	for _, e := range r.TopDirs {
+		fmt.Fprintf(w, "disk_eaters_dir_bytes{path=%s} %d\n", quoteLabel(e.Path), e.Size)
+	}
+
+	fmt.Fprintln(w, "# HELP disk_eaters_file_bytes Size of one of the largest files found.")
+	fmt.Fprintln(w, "# TYPE disk_eaters_file_bytes gauge")
+	for _, e := range r.TopFiles {
+		fmt.Fprintf(w, "disk_eaters_file_bytes{path=%s} %d\n", quoteLabel(e.Path), e.Size)
+	}
+
+	fmt.Fprintln(w, "# HELP disk_eaters_growth_bytes_per_hour Average growth rate over the window since the nearest eligible snapshot.")
+	fmt.Fprintln(w, "# TYPE disk_eaters_growth_bytes_per_hour gauge")
+	for _, gw := range r.Growth {
+		hours := windowHours(gw.Label)
+		for _, e := range gw.Dirs {
+			fmt.Fprintf(w, "disk_eaters_growth_bytes_per_hour{path=%s,kind=\"dir\",window=%s} %g\n",
+				quoteLabel(e.Path), quoteLabel(gw.Label), float64(e.Size)/hours)
+		}
+		for _, e := range gw.Files {
+			fmt.Fprintf(w, "disk_eaters_growth_bytes_per_hour{path=%s,kind=\"file\",window=%s} %g\n",
+				quoteLabel(e.Path), quoteLabel(gw.Label), float64(e.Size)/hours)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP disk_eaters_days_to_capacity Projected days until a path's growth exhausts free space.")
+	fmt.Fprintln(w, "# TYPE disk_eaters_days_to_capacity gauge")
+	for _, p := range r.Projections {
+		fmt.Fprintf(w, "disk_eaters_days_to_capacity{path=%s,window=%s} %g\n",
+			quoteLabel(p.Path), quoteLabel(p.Window), p.DaysToCapacity)
+	}
+
+	return nil
+}
+
+// windowHours converts a GrowthWindow label ("1d", "7d", "30d", ...) to
+// hours, for deriving a per-hour rate from a total-growth-over-window
+// figure. Unrecognized labels fall back to 24h so the metric still
+// renders something sane.
+func windowHours(label string) float64 {
+	switch label {
+	case "1d":
+		return 24
+	case "7d":
+		return 7 * 24
+	case "30d":
+		return 30 * 24
+	default:
+		return 24
+	}
+}
+
+// quoteLabel escapes path for use as a Prometheus label value.
+func quoteLabel(path string) string {
+	return strconv.Quote(path)
+}
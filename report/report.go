@@ -0,0 +1,82 @@
+// Package report holds the structured result of a single disk_eaters scan
+// and the sinks that render it: human-readable text, newline-delimited
+// JSON, and Prometheus text format. Every sink formats from the same
+// Report so scan results never need to be computed twice for two views.
+package report
+
+import (
+	"fmt"
+	"time"
+)
+
+// Entry is a path and its size, used for both directory and file views
+// (TopDirs/TopFiles) and for growth views (GrowthWindow's Dirs/Files,
+// where Size is the change since the previous snapshot).
+type Entry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// ProcessUse is one process holding a file open, associated back to the
+// file by path so sinks can group by file however they like.
+type ProcessUse struct {
+	File    string `json:"file"`
+	PID     string `json:"pid"`
+	User    string `json:"user"`
+	Command string `json:"command"`
+	Access  string `json:"access"`
+}
+
+// GrowthWindow is the growth in directory and file sizes over one
+// historical interval (e.g. "1d", "7d", "30d"), each computed against
+// the nearest retained snapshot at least that old.
+type GrowthWindow struct {
+	Label string  `json:"label"`
+	Dirs  []Entry `json:"dirs"`
+	Files []Entry `json:"files"`
+}
+
+// Projection is a linear-regression estimate, from a path's growth over
+// Window, of how many days remain before that growth alone exhausts the
+// free space on the scanned filesystem.
+type Projection struct {
+	Path           string  `json:"path"`
+	Window         string  `json:"window"`
+	BytesPerDay    float64 `json:"bytes_per_day"`
+	DaysToCapacity float64 `json:"days_to_capacity"`
+}
+
+// Report is the single structured result of one scan.
+type Report struct {
+	Timestamp   time.Time      `json:"timestamp"`
+	ScanRoot    string         `json:"scan_root"`
+	TopDirs     []Entry        `json:"top_dirs"`
+	TopFiles    []Entry        `json:"top_files"`
+	Growth      []GrowthWindow `json:"growth"`
+	Projections []Projection   `json:"projections,omitempty"`
+	Processes   []ProcessUse   `json:"processes,omitempty"`
+}
+
+const (
+	KB = 1024
+	MB = KB * 1024
+	GB = MB * 1024
+	TB = GB * 1024
+)
+
+// FormatSize converts a byte count to a human-readable string, e.g.
+// "4.00 GB".
+func FormatSize(sizeInBytes int64) string {
+	switch {
+	case sizeInBytes >= TB:
+		return fmt.Sprintf("%.2f TB", float64(sizeInBytes)/float64(TB))
+	case sizeInBytes >= GB:
+		return fmt.Sprintf("%.2f GB", float64(sizeInBytes)/float64(GB))
+	case sizeInBytes >= MB:
+		return fmt.Sprintf("%.2f MB", float64(sizeInBytes)/float64(MB))
+	case sizeInBytes >= KB:
+		return fmt.Sprintf("%.2f KB", float64(sizeInBytes)/float64(KB))
+	default:
+		return fmt.Sprintf("%d B", sizeInBytes)
+	}
+}
@@ -0,0 +1,44 @@
+package report
+
+import (
+	"net"
+	"net/http"
+)
+
+// Serve starts an HTTP server on addr exposing /metrics (Prometheus text
+// format) and /report.json (newline-delimited JSON, one line), both
+// rendered from whatever get returns at request time. It returns
+// immediately; the server keeps running until the process exits or the
+// returned *http.Server is shut down.
+func Serve(addr string, get func() *Report) (*http.Server, error) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, req *http.Request) {
+		r := get()
+		if r == nil {
+			http.Error(w, "no report available yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w, r)
+	})
+
+	mux.HandleFunc("/report.json", func(w http.ResponseWriter, req *http.Request) {
+		r := get()
+		if r == nil {
+			http.Error(w, "no report available yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		WriteJSON(w, r)
+	})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go srv.Serve(ln)
+	return srv, nil
+}
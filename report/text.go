@@ -0,0 +1,96 @@
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteText renders r in the human-readable format disk_eaters has always
+// printed to the console and its history log.
+func WriteText(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "DISK EATERS WATCH REPORT - %s\n", r.Timestamp.Format("2006-01-02"))
+	fmt.Fprintf(w, "Scan Directory: %s\n\n", r.ScanRoot)
+
+	PrintHeader(w, fmt.Sprintf("TOP %d LARGEST DIRECTORIES UNDER %s", len(r.TopDirs), r.ScanRoot))
+	for _, e := range r.TopDirs {
+		fmt.Fprintf(w, "%s\t%s\n", FormatSize(e.Size), e.Path)
+	}
+	fmt.Fprintln(w)
+
+	PrintHeader(w, fmt.Sprintf("TOP %d LARGEST FILES UNDER %s", len(r.TopFiles), r.ScanRoot))
+	for _, e := range r.TopFiles {
+		fmt.Fprintf(w, "%s\t%s\n", FormatSize(e.Size), e.Path)
+	}
+
+	if r.Processes != nil && len(r.TopFiles) > 0 {
+		fmt.Fprintln(w)
+		PrintHeader(w, "PROCESSES USING LARGE FILES")
+		writeProcessTable(w, r.TopFiles, r.Processes)
+	}
+	fmt.Fprintln(w)
+
+	for _, gw := range r.Growth {
+		PrintHeader(w, fmt.Sprintf("FASTEST GROWING DIRECTORIES UNDER %s (last %s)", r.ScanRoot, gw.Label))
+		writeGrowth(w, gw.Dirs)
+		fmt.Fprintln(w)
+
+		PrintHeader(w, fmt.Sprintf("FASTEST GROWING FILES UNDER %s (last %s)", r.ScanRoot, gw.Label))
+		writeGrowth(w, gw.Files)
+		fmt.Fprintln(w)
+	}
+
+	if len(r.Projections) > 0 {
+		PrintHeader(w, "CAPACITY PROJECTIONS")
+		writeProjections(w, r.Projections)
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+func writeGrowth(w io.Writer, entries []Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No previous data available for comparison. Growth analysis will be available after the next run.")
+		return
+	}
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\n", FormatSize(e.Size), e.Path)
+	}
+}
+
+func writeProcessTable(w io.Writer, files []Entry, processes []ProcessUse) {
+	for _, file := range files {
+		fmt.Fprintf(w, "\nFile: %s (%s)\n", file.Path, FormatSize(file.Size))
+
+		var procs []ProcessUse
+		for _, p := range processes {
+			if p.File == file.Path {
+				procs = append(procs, p)
+			}
+		}
+
+		if len(procs) == 0 {
+			fmt.Fprintf(w, "  No processes currently using this file\n")
+			continue
+		}
+		fmt.Fprintf(w, "  %-8s %-10s %-8s %s\n", "PID", "USER", "ACCESS", "COMMAND")
+		fmt.Fprintf(w, "  %-8s %-10s %-8s %s\n", "---", "----", "------", "-------")
+		for _, p := range procs {
+			fmt.Fprintf(w, "  %-8s %-10s %-8s %s\n", p.PID, p.User, p.Access, p.Command)
+		}
+	}
+}
+
+func writeProjections(w io.Writer, projections []Projection) {
+	for _, p := range projections {
+		fmt.Fprintf(w, "%s: growing %s/day over the last %s, fills remaining free space in %.1f days\n",
+			p.Path, FormatSize(int64(p.BytesPerDay)), p.Window, p.DaysToCapacity)
+	}
+}
+
+// PrintHeader prints a formatted header to the given writer.
+func PrintHeader(w io.Writer, header string) {
+	fmt.Fprintln(w, "==================================================")
+	fmt.Fprintf(w, "  %s\n", header)
+	fmt.Fprintln(w, "==================================================")
+}
@@ -0,0 +1,159 @@
+//go:build linux
+
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// CgroupStats holds the I/O and memory figures read from a process's
+// cgroup, preferring the unified (v2) hierarchy and falling back to v1.
+type CgroupStats struct {
+	IOReadBytes        int64
+	IOWriteBytes       int64
+	MemoryCurrent      int64
+	MemoryPressureSome float64 // avg10 from memory.pressure, v2 only
+}
+
+func cgroupStatsForPid(pid int) (*CgroupStats, error) {
+	subpath, err := cgroupPathForPid(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats, err := cgroupStatsV2(subpath); err == nil {
+		return stats, nil
+	}
+	return cgroupStatsV1(subpath)
+}
+
+// cgroupPathForPid reads /proc/<pid>/cgroup and returns the subpath under
+// cgroupRoot. Under the unified hierarchy every line reads "0::<path>";
+// under v1 each controller has its own line and any one gives the path.
+func cgroupPathForPid(pid int) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) == 3 {
+			return parts[2], nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup entry for pid %d", pid)
+}
+
+func cgroupStatsV2(subpath string) (*CgroupStats, error) {
+	dir := filepath.Join(cgroupRoot, subpath)
+	ioStat, err := os.ReadFile(filepath.Join(dir, "io.stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CgroupStats{}
+	stats.IOReadBytes, stats.IOWriteBytes = parseIOStat(ioStat)
+
+	if data, err := os.ReadFile(filepath.Join(dir, "memory.current")); err == nil {
+		stats.MemoryCurrent, _ = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "memory.pressure")); err == nil {
+		stats.MemoryPressureSome = parsePressureSomeAvg10(string(data))
+	}
+
+	return stats, nil
+}
+
+// parseIOStat sums the rbytes/wbytes fields across every device line of a
+// cgroup v2 io.stat file. A cgroup that has done no block I/O yet has an
+// empty io.stat, which must parse as zero rather than panic.
+func parseIOStat(data []byte) (readBytes, writeBytes int64) {
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, kv := range fields[1:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			v, _ := strconv.ParseInt(parts[1], 10, 64)
+			switch parts[0] {
+			case "rbytes":
+				readBytes += v
+			case "wbytes":
+				writeBytes += v
+			}
+		}
+	}
+	return readBytes, writeBytes
+}
+
+func cgroupStatsV1(subpath string) (*CgroupStats, error) {
+	stats := &CgroupStats{}
+
+	blkio, err := os.Open(filepath.Join(cgroupRoot, "blkio", subpath, "blkio.throttle.io_service_bytes"))
+	if err != nil {
+		return nil, err
+	}
+	defer blkio.Close()
+
+	scanner := bufio.NewScanner(blkio)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		v, _ := strconv.ParseInt(fields[2], 10, 64)
+		switch fields[1] {
+		case "Read":
+			stats.IOReadBytes += v
+		case "Write":
+			stats.IOWriteBytes += v
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(cgroupRoot, "memory", subpath, "memory.stat")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == "rss" {
+				stats.MemoryCurrent, _ = strconv.ParseInt(fields[1], 10, 64)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// parsePressureSomeAvg10 extracts avg10 from a memory.pressure "some" line,
+// e.g. "some avg10=0.00 avg60=0.00 avg300=0.00 total=0".
+func parsePressureSomeAvg10(data string) float64 {
+	for _, line := range strings.Split(data, "\n") {
+		if !strings.HasPrefix(line, "some ") {
+			continue
+		}
+		for _, field := range strings.Fields(line)[1:] {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) == 2 && parts[0] == "avg10" {
+				v, _ := strconv.ParseFloat(parts[1], 64)
+				return v
+			}
+		}
+	}
+	return 0
+}
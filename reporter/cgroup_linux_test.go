@@ -0,0 +1,20 @@
+//go:build linux
+
+package reporter
+
+import "testing"
+
+func TestParseIOStatEmpty(t *testing.T) {
+	read, write := parseIOStat([]byte(""))
+	if read != 0 || write != 0 {
+		t.Fatalf("parseIOStat(\"\") = %d, %d; want 0, 0", read, write)
+	}
+}
+
+func TestParseIOStatSumsAcrossDevices(t *testing.T) {
+	data := "8:0 rbytes=100 wbytes=200 rios=1 wios=1\n8:16 rbytes=300 wbytes=400 rios=2 wios=2\n"
+	read, write := parseIOStat([]byte(data))
+	if read != 400 || write != 600 {
+		t.Fatalf("parseIOStat(...) = %d, %d; want 400, 600", read, write)
+	}
+}
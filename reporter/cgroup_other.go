@@ -0,0 +1,18 @@
+//go:build !linux
+
+package reporter
+
+import "fmt"
+
+// CgroupStats holds the I/O and memory figures read from a process's
+// cgroup. Only populated on linux.
+type CgroupStats struct {
+	IOReadBytes        int64
+	IOWriteBytes       int64
+	MemoryCurrent      int64
+	MemoryPressureSome float64
+}
+
+func cgroupStatsForPid(pid int) (*CgroupStats, error) {
+	return nil, fmt.Errorf("cgroup inspection is only supported on linux")
+}
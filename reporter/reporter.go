@@ -0,0 +1,227 @@
+// Package reporter implements a long-running sampler that periodically
+// measures disk usage for a set of paths and, when a target process is
+// given, correlates growth with that process's cgroup I/O and memory
+// pressure. It is modeled on the crunchstat Reporter pattern: a single
+// ticker drives a sample/diff/log cycle, with threshold crossings logged
+// separately (and with hysteresis) from routine samples.
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Sample is one poll's disk usage measurement for a single path.
+type Sample struct {
+	Path        string
+	Time        time.Time
+	TotalBytes  uint64
+	FreeBytes   uint64
+	TotalInodes uint64
+	FreeInodes  uint64
+}
+
+// Threshold metric names usable as the suffix of a Thresholds key
+// ("<path>:<metric>").
+const (
+	ThresholdFreeBytes       = "free-bytes"
+	ThresholdInodePercent    = "inode-percent"
+	ThresholdGrowthPerMinute = "growth-per-minute"
+)
+
+// Reporter periodically samples disk usage for Paths (plus TempDir) and
+// logs both routine samples and threshold crossings.
+type Reporter struct {
+	// Paths are statfs'd on every tick.
+	Paths []string
+	// TempDir is statfs'd alongside Paths on every tick, mirroring
+	// crunchstat's practice of always watching its own scratch space.
+	TempDir string
+	// PollPeriod is how often to sample. Defaults to 10s.
+	PollPeriod time.Duration
+	// Thresholds maps "<path>:<metric>" (metric being one of the
+	// Threshold* constants) to a [limit] to alert on. free-bytes and
+	// growth-per-minute limits are byte counts; inode-percent is 0-100.
+	Thresholds map[string][]int64
+	// Logger receives one line per sample. Nil disables sample logging.
+	Logger io.Writer
+	// ThresholdLogger receives one line whenever a threshold is crossed
+	// or clears. Nil disables threshold logging.
+	ThresholdLogger io.Writer
+	// Pid, if set, is called on every tick to get the process whose
+	// cgroup I/O and memory pressure should be logged alongside the
+	// path samples. A non-positive result skips cgroup reporting for
+	// that tick.
+	Pid func() int
+
+	mu       sync.Mutex
+	previous map[string]Sample
+	crossed  map[string]bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Start begins sampling in the background. It is not safe to call Start
+// more than once on the same Reporter.
+func (r *Reporter) Start() {
+	if r.PollPeriod <= 0 {
+		r.PollPeriod = 10 * time.Second
+	}
+	r.previous = make(map[string]Sample)
+	r.crossed = make(map[string]bool)
+	r.stop = make(chan struct{})
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.PollPeriod)
+		defer ticker.Stop()
+
+		r.tick()
+		for {
+			select {
+			case <-ticker.C:
+				r.tick()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends sampling and waits for the current tick, if any, to finish.
+func (r *Reporter) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+func (r *Reporter) paths() []string {
+	paths := append([]string(nil), r.Paths...)
+	if r.TempDir == "" {
+		return paths
+	}
+	for _, p := range paths {
+		if p == r.TempDir {
+			return paths
+		}
+	}
+	return append(paths, r.TempDir)
+}
+
+func (r *Reporter) tick() {
+	now := time.Now()
+
+	for _, path := range r.paths() {
+		sample, err := statPath(path, now)
+		if err != nil {
+			r.logf(r.Logger, "path=%s error=%q", path, err)
+			continue
+		}
+
+		r.logf(r.Logger, "path=%s total_bytes=%d free_bytes=%d total_inodes=%d free_inodes=%d",
+			sample.Path, sample.TotalBytes, sample.FreeBytes, sample.TotalInodes, sample.FreeInodes)
+		r.checkThresholds(sample)
+
+		r.mu.Lock()
+		r.previous[path] = sample
+		r.mu.Unlock()
+	}
+
+	if r.Pid == nil {
+		return
+	}
+	if pid := r.Pid(); pid > 0 {
+		r.logCgroup(pid)
+	}
+}
+
+// checkThresholds compares sample against any configured limits for its
+// path and logs a line the first time a limit is crossed in either
+// direction, so a value flapping around a limit doesn't spam the log.
+func (r *Reporter) checkThresholds(sample Sample) {
+	r.mu.Lock()
+	prev, hadPrev := r.previous[sample.Path]
+	r.mu.Unlock()
+
+	metrics := map[string]float64{
+		ThresholdFreeBytes:    float64(sample.FreeBytes),
+		ThresholdInodePercent: inodePercent(sample),
+	}
+	if hadPrev {
+		if elapsed := sample.Time.Sub(prev.Time).Minutes(); elapsed > 0 {
+			shrunk := float64(prev.FreeBytes) - float64(sample.FreeBytes)
+			metrics[ThresholdGrowthPerMinute] = shrunk / elapsed
+		}
+	}
+
+	for metric, value := range metrics {
+		key := sample.Path + ":" + metric
+		limits, ok := r.Thresholds[key]
+		if !ok || len(limits) == 0 {
+			continue
+		}
+		limit := float64(limits[0])
+
+		// free-bytes alerts when the remaining space drops to or below
+		// the limit; the others alert when the value meets or exceeds it.
+		over := value >= limit
+		if metric == ThresholdFreeBytes {
+			over = value <= limit
+		}
+
+		r.mu.Lock()
+		was := r.crossed[key]
+		r.crossed[key] = over
+		r.mu.Unlock()
+
+		switch {
+		case over && !was:
+			r.logf(r.ThresholdLogger, "THRESHOLD CROSSED path=%s metric=%s value=%.0f limit=%.0f",
+				sample.Path, metric, value, limit)
+		case !over && was:
+			r.logf(r.ThresholdLogger, "threshold cleared path=%s metric=%s value=%.0f limit=%.0f",
+				sample.Path, metric, value, limit)
+		}
+	}
+}
+
+func (r *Reporter) logCgroup(pid int) {
+	stats, err := cgroupStatsForPid(pid)
+	if err != nil {
+		r.logf(r.Logger, "pid=%d cgroup_error=%q", pid, err)
+		return
+	}
+	r.logf(r.Logger, "pid=%d io_read_bytes=%d io_write_bytes=%d memory_current_bytes=%d memory_pressure_some_avg10=%.2f",
+		pid, stats.IOReadBytes, stats.IOWriteBytes, stats.MemoryCurrent, stats.MemoryPressureSome)
+}
+
+func (r *Reporter) logf(w io.Writer, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	fmt.Fprintf(w, "time=%s "+format+"\n",
+		append([]interface{}{time.Now().Format(time.RFC3339)}, args...)...)
+}
+
+// FreeBytes reports the number of free bytes on the filesystem containing
+// path. It is a thin wrapper around the same platform statfs used by the
+// polling loop, for callers (like growth projections) that just need one
+// reading outside of a Reporter.
+func FreeBytes(path string) (uint64, error) {
+	sample, err := statPath(path, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return sample.FreeBytes, nil
+}
+
+func inodePercent(s Sample) float64 {
+	if s.TotalInodes == 0 {
+		return 0
+	}
+	used := s.TotalInodes - s.FreeInodes
+	return float64(used) / float64(s.TotalInodes) * 100
+}
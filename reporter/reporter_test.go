@@ -0,0 +1,46 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckThresholdsHysteresis(t *testing.T) {
+	var log strings.Builder
+	r := &Reporter{
+		Thresholds: map[string][]int64{
+			"/data:free-bytes": {1000},
+		},
+		ThresholdLogger: &log,
+	}
+	r.previous = make(map[string]Sample)
+	r.crossed = make(map[string]bool)
+
+	now := time.Now()
+
+	// Above the limit: no crossing logged.
+	r.checkThresholds(Sample{Path: "/data", Time: now, FreeBytes: 5000})
+	if log.Len() != 0 {
+		t.Fatalf("unexpected log before crossing: %q", log.String())
+	}
+
+	// Drops below the limit: exactly one crossing logged.
+	r.checkThresholds(Sample{Path: "/data", Time: now, FreeBytes: 500})
+	if !strings.Contains(log.String(), "THRESHOLD CROSSED") {
+		t.Fatalf("expected a crossing to be logged, got %q", log.String())
+	}
+	afterFirstCross := log.Len()
+
+	// Still below the limit: no repeated crossing (hysteresis).
+	r.checkThresholds(Sample{Path: "/data", Time: now, FreeBytes: 400})
+	if log.Len() != afterFirstCross {
+		t.Fatalf("expected no additional log line while still over the limit, got %q", log.String())
+	}
+
+	// Recovers above the limit: a single "cleared" line.
+	r.checkThresholds(Sample{Path: "/data", Time: now, FreeBytes: 5000})
+	if !strings.Contains(log.String(), "threshold cleared") {
+		t.Fatalf("expected a cleared line after recovering, got %q", log.String())
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package reporter
+
+import (
+	"syscall"
+	"time"
+)
+
+func statPath(path string, now time.Time) (Sample, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return Sample{}, err
+	}
+
+	bsize := uint64(st.Bsize)
+	return Sample{
+		Path:        path,
+		Time:        now,
+		TotalBytes:  uint64(st.Blocks) * bsize,
+		FreeBytes:   uint64(st.Bavail) * bsize,
+		TotalInodes: uint64(st.Files),
+		FreeInodes:  uint64(st.Ffree),
+	}, nil
+}
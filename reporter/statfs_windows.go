@@ -0,0 +1,41 @@
+//go:build windows
+
+package reporter
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	modkernel32          = syscall.NewLazyDLL("kernel32.dll")
+	procGetDiskFreeSpace = modkernel32.NewProc("GetDiskFreeSpaceExW")
+)
+
+// statPath has no inode concept on Windows, so TotalInodes/FreeInodes are
+// left zero; inode-percent thresholds are simply never triggered there.
+func statPath(path string, now time.Time) (Sample, error) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	var freeBytes, totalBytes, totalFreeBytes uint64
+	ret, _, callErr := procGetDiskFreeSpace.Call(
+		uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&freeBytes)),
+		uintptr(unsafe.Pointer(&totalBytes)),
+		uintptr(unsafe.Pointer(&totalFreeBytes)),
+	)
+	if ret == 0 {
+		return Sample{}, callErr
+	}
+
+	return Sample{
+		Path:       path,
+		Time:       now,
+		TotalBytes: totalBytes,
+		FreeBytes:  freeBytes,
+	}, nil
+}
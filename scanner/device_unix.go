@@ -0,0 +1,15 @@
+//go:build !windows
+
+package scanner
+
+import "syscall"
+
+// deviceOf returns the st_dev of path, used to detect mountpoint
+// boundaries so SameFilesystem can stop recursion at them.
+func deviceOf(path string) (uint64, bool) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
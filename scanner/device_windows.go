@@ -0,0 +1,58 @@
+//go:build windows
+
+package scanner
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// deviceOf returns the volume serial number of path, Windows' equivalent
+// of st_dev, via GetVolumeInformationByHandle on an open handle to it.
+func deviceOf(path string) (uint64, bool) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false
+	}
+
+	h, err := syscall.CreateFile(p,
+		0, // no access needed, just metadata
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		syscall.FILE_FLAG_BACKUP_SEMANTICS,
+		0)
+	if err != nil {
+		return 0, false
+	}
+	defer syscall.CloseHandle(h)
+
+	var volumeSerial uint32
+	ok := getVolumeInformationByHandle(h, nil, 0, &volumeSerial, nil, nil, nil, 0)
+	if !ok {
+		return 0, false
+	}
+	return uint64(volumeSerial), true
+}
+
+var (
+	modkernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetVolumeInformationByHandleW = modkernel32.NewProc("GetVolumeInformationByHandleW")
+)
+
+func getVolumeInformationByHandle(h syscall.Handle, volumeNameBuffer *uint16, volumeNameSize uint32,
+	volumeSerialNumber *uint32, maxComponentLen *uint32, fileSystemFlags *uint32,
+	fileSystemNameBuffer *uint16, fileSystemNameSize uint32) bool {
+
+	ret, _, _ := procGetVolumeInformationByHandleW.Call(
+		uintptr(h),
+		uintptr(unsafe.Pointer(volumeNameBuffer)),
+		uintptr(volumeNameSize),
+		uintptr(unsafe.Pointer(volumeSerialNumber)),
+		uintptr(unsafe.Pointer(maxComponentLen)),
+		uintptr(unsafe.Pointer(fileSystemFlags)),
+		uintptr(unsafe.Pointer(fileSystemNameBuffer)),
+		uintptr(fileSystemNameSize),
+	)
+	return ret != 0
+}
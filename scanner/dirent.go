@@ -0,0 +1,39 @@
+package scanner
+
+import "os"
+
+// dirent is the handful of fields Scan needs from a directory entry. It is
+// populated with a single os.DirEntry.Info() call so callers avoid the
+// extra per-entry os.Stat a filepath.Walk-based walker would pay.
+type dirent struct {
+	name      string
+	isDir     bool
+	isSymlink bool
+	isRegular bool
+	size      int64
+}
+
+func readDir(path string) ([]dirent, error) {
+	raw, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]dirent, 0, len(raw))
+	for _, e := range raw {
+		info, err := e.Info()
+		if err != nil {
+			// Entry vanished between ReadDir and Info (e.g. a racing
+			// delete); skip it rather than failing the whole directory.
+			continue
+		}
+		out = append(out, dirent{
+			name:      e.Name(),
+			isDir:     e.IsDir(),
+			isSymlink: info.Mode()&os.ModeSymlink != 0,
+			isRegular: info.Mode().IsRegular(),
+			size:      info.Size(),
+		})
+	}
+	return out, nil
+}
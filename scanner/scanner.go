@@ -0,0 +1,326 @@
+// Package scanner implements a single-pass concurrent directory walker.
+//
+// A Scan reads each directory exactly once with os.ReadDir, fans out
+// subdirectories over a bounded worker pool, and reduces the results back
+// up the tree as children complete. Recursive directory sizes and the
+// largest files encountered are both collected from that one pass, so
+// callers never need to walk the tree twice to get both views.
+package scanner
+
+import (
+	"container/heap"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ScanOptions controls how Scan walks the filesystem.
+type ScanOptions struct {
+	// Workers is the number of directories read concurrently. <=0 means
+	// runtime.NumCPU().
+	Workers int
+	// MaxDepth limits recursion to this many levels below root. <=0 means
+	// unlimited.
+	MaxDepth int
+	// Exclude holds glob patterns (matched with filepath.Match) and path
+	// prefixes to skip, e.g. "/proc", "/sys", "/dev", "/run".
+	Exclude []string
+	// SameFilesystem stops recursion at mountpoints, like `find -xdev`.
+	SameFilesystem bool
+	// FollowSymlinks recurses into symlinked directories instead of
+	// skipping them.
+	FollowSymlinks bool
+	// TopFiles is how many of the largest files to keep. <=0 means 10.
+	TopFiles int
+}
+
+// DirEntry is a directory and its recursive size, including all of its
+// descendants.
+type DirEntry struct {
+	Path string
+	Size int64
+}
+
+// FileEntry is a single regular file and its size.
+type FileEntry struct {
+	Path string
+	Size int64
+}
+
+// ScanResult is the outcome of a single Scan: every directory visited with
+// its recursive size, and the largest files found along the way. Both are
+// sorted largest first.
+type ScanResult struct {
+	Dirs  []DirEntry
+	Files []FileEntry
+}
+
+// dirNode tracks one directory's reduction state. pending starts at 1 to
+// represent the directory's own listing; it is incremented once per
+// dispatched child and decremented when the listing finishes and whenever
+// a child completes. When it reaches zero, the node is fully reduced (own
+// files plus every descendant) and its total is folded into its parent.
+type dirNode struct {
+	path string
+	// real is path with every symlink in the chain down from root
+	// resolved. It equals path unless a FollowSymlinks hop was taken to
+	// reach this node, in which case it's used to detect a symlink
+	// pointing back at one of its own ancestors.
+	real    string
+	depth   int
+	parent  *dirNode
+	size    int64
+	pending int32
+}
+
+func newDirNode(path, real string, depth int, parent *dirNode) *dirNode {
+	return &dirNode{path: path, real: real, depth: depth, parent: parent, pending: 1}
+}
+
+// symlinkCycle reports whether real (a symlink target already resolved by
+// the caller) is one of n's own ancestors, i.e. following it would revisit
+// a directory currently being scanned rather than descend into a new one.
+func symlinkCycle(n *dirNode, real string) bool {
+	for anc := n; anc != nil; anc = anc.parent {
+		if anc.real == real {
+			return true
+		}
+	}
+	return false
+}
+
+type scan struct {
+	opts    ScanOptions
+	rootDev uint64
+	hasDev  bool
+
+	jobs chan *dirNode
+	wg   sync.WaitGroup // outstanding "not yet listed" directories
+	done chan struct{}  // closed once the root node is fully reduced
+
+	mu    sync.Mutex
+	dirs  []DirEntry
+	files *fileHeap
+}
+
+// Scan walks root once, computing the recursive size of every directory
+// and tracking the largest regular files, without walking the tree a
+// second time for each view.
+func Scan(root string, opts ScanOptions) (*ScanResult, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.TopFiles <= 0 {
+		opts.TopFiles = 10
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	rootReal := absRoot
+	if resolved, err := filepath.EvalSymlinks(absRoot); err == nil {
+		rootReal = resolved
+	}
+
+	s := &scan{
+		opts:  opts,
+		jobs:  make(chan *dirNode, 1024),
+		done:  make(chan struct{}),
+		files: &fileHeap{},
+	}
+	if dev, ok := deviceOf(absRoot); ok {
+		s.rootDev, s.hasDev = dev, true
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		workers.Add(1)
+		go s.work(&workers)
+	}
+
+	root_ := newDirNode(absRoot, rootReal, 0, nil)
+	s.wg.Add(1)
+	s.jobs <- root_
+
+	go func() {
+		s.wg.Wait()
+		close(s.jobs)
+	}()
+
+	<-s.done
+	workers.Wait()
+
+	sort.Slice(s.dirs, func(i, j int) bool { return s.dirs[i].Size > s.dirs[j].Size })
+
+	files := append([]FileEntry(nil), (*s.files)...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+
+	return &ScanResult{Dirs: s.dirs, Files: files}, nil
+}
+
+func (s *scan) work(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for n := range s.jobs {
+		s.list(n)
+	}
+}
+
+// dispatch hands a child directory to the job channel without blocking the
+// calling worker. Workers both produce (via list, below) and consume jobs
+// from the same bounded channel; if dispatch sent directly, a wide
+// directory could fill the channel while every worker is itself blocked
+// trying to send, and nothing would ever drain it. A send that would
+// block is queued in its own goroutine instead, decoupling production
+// from the fixed-size worker pool so workers always stay available to
+// consume; the common case of room in the buffer sends directly and
+// spawns nothing.
+func (s *scan) dispatch(n *dirNode) {
+	select {
+	case s.jobs <- n:
+	default:
+		go func() { s.jobs <- n }()
+	}
+}
+
+func (s *scan) list(n *dirNode) {
+	defer func() {
+		s.wg.Done()
+		s.reduce(n)
+	}()
+
+	if s.excluded(n.path) {
+		return
+	}
+
+	entries, err := readDir(n.path)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		full := filepath.Join(n.path, entry.name)
+
+		isDir := entry.isDir
+		real := filepath.Join(n.real, entry.name)
+		if entry.isSymlink {
+			// DirEntry.IsDir() reports the d_type from the directory
+			// listing itself, which is never "directory" for a symlink no
+			// matter what it points to. Only pay for the extra follow-Stat
+			// when the caller actually asked to recurse into symlinks.
+			if !s.opts.FollowSymlinks {
+				continue
+			}
+			info, err := os.Stat(full)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			resolved, err := filepath.EvalSymlinks(full)
+			if err != nil {
+				continue
+			}
+			// A symlink pointing back at one of its own ancestors would
+			// otherwise recurse into the same directory forever.
+			if symlinkCycle(n, resolved) {
+				continue
+			}
+			isDir, real = true, resolved
+		}
+
+		if isDir {
+			if s.opts.MaxDepth > 0 && n.depth+1 > s.opts.MaxDepth {
+				continue
+			}
+			if s.excluded(full) {
+				continue
+			}
+			if s.opts.SameFilesystem && s.hasDev {
+				if dev, ok := deviceOf(full); ok && dev != s.rootDev {
+					continue
+				}
+			}
+
+			child := newDirNode(full, real, n.depth+1, n)
+			atomic.AddInt32(&n.pending, 1)
+			s.wg.Add(1)
+			s.dispatch(child)
+			continue
+		}
+
+		if !entry.isRegular {
+			continue
+		}
+		atomic.AddInt64(&n.size, entry.size)
+		s.trackFile(FileEntry{Path: full, Size: entry.size})
+	}
+}
+
+// reduce removes one unit of n's outstanding work (its own listing, or a
+// child that just finished). Once none remain, n is fully reduced: it is
+// recorded and its total is folded into its parent, recursing up the tree.
+func (s *scan) reduce(n *dirNode) {
+	if atomic.AddInt32(&n.pending, -1) != 0 {
+		return
+	}
+
+	size := atomic.LoadInt64(&n.size)
+	s.mu.Lock()
+	s.dirs = append(s.dirs, DirEntry{Path: n.path, Size: size})
+	s.mu.Unlock()
+
+	if n.parent == nil {
+		close(s.done)
+		return
+	}
+	atomic.AddInt64(&n.parent.size, size)
+	s.reduce(n.parent)
+}
+
+func (s *scan) excluded(path string) bool {
+	for _, pattern := range s.opts.Exclude {
+		if pattern == "" {
+			continue
+		}
+		if path == pattern || strings.HasPrefix(path, pattern+string(filepath.Separator)) {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scan) trackFile(f FileEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files.Len() < s.opts.TopFiles {
+		heap.Push(s.files, f)
+		return
+	}
+	if f.Size > (*s.files)[0].Size {
+		heap.Pop(s.files)
+		heap.Push(s.files, f)
+	}
+}
+
+// fileHeap is a min-heap of FileEntry, used to keep the TopFiles largest
+// files seen without retaining every file in the tree.
+type fileHeap []FileEntry
+
+func (h fileHeap) Len() int            { return len(h) }
+func (h fileHeap) Less(i, j int) bool  { return h[i].Size < h[j].Size }
+func (h fileHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *fileHeap) Push(x interface{}) { *h = append(*h, x.(FileEntry)) }
+func (h *fileHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
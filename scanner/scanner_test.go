@@ -0,0 +1,208 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// buildTree creates a directory tree depth levels deep with width entries
+// (files and subdirectories) at each level, for benchmarking.
+func buildTree(tb testing.TB, depth, width int) string {
+	tb.Helper()
+	root := tb.TempDir()
+
+	var populate func(path string, depth int)
+	populate = func(path string, depth int) {
+		for i := 0; i < width; i++ {
+			file := filepath.Join(path, fmt.Sprintf("file-%d.txt", i))
+			if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+				tb.Fatal(err)
+			}
+		}
+		if depth == 0 {
+			return
+		}
+		for i := 0; i < width; i++ {
+			dir := filepath.Join(path, fmt.Sprintf("dir-%d", i))
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				tb.Fatal(err)
+			}
+			populate(dir, depth-1)
+		}
+	}
+	populate(root, depth)
+	return root
+}
+
+func TestScanAggregatesDirectorySizes(t *testing.T) {
+	root := buildTree(t, 2, 3)
+
+	result, err := Scan(root, ScanOptions{Workers: 4, TopFiles: 5})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	var rootEntry *DirEntry
+	for i := range result.Dirs {
+		if result.Dirs[i].Path == root {
+			rootEntry = &result.Dirs[i]
+		}
+	}
+	if rootEntry == nil {
+		t.Fatalf("root %q missing from Dirs", root)
+	}
+	if rootEntry.Size == 0 {
+		t.Fatalf("root size is 0, expected the recursive total of every file under it")
+	}
+
+	for i := 1; i < len(result.Dirs); i++ {
+		if result.Dirs[i-1].Size < result.Dirs[i].Size {
+			t.Fatalf("Dirs not sorted largest first at index %d", i)
+		}
+	}
+	for i := 1; i < len(result.Files); i++ {
+		if result.Files[i-1].Size < result.Files[i].Size {
+			t.Fatalf("Files not sorted largest first at index %d", i)
+		}
+	}
+}
+
+func TestScanExcludesPaths(t *testing.T) {
+	root := buildTree(t, 1, 2)
+	excluded := filepath.Join(root, "dir-0")
+
+	result, err := Scan(root, ScanOptions{Workers: 2, TopFiles: 10, Exclude: []string{excluded}})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	for _, d := range result.Dirs {
+		if d.Path == excluded {
+			t.Fatalf("excluded directory %q was still scanned", excluded)
+		}
+	}
+}
+
+func TestScanFollowsSymlinkedDirectories(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "target")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	result, err := Scan(root, ScanOptions{Workers: 2, TopFiles: 10, FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	var linkEntry *DirEntry
+	for i := range result.Dirs {
+		if result.Dirs[i].Path == link {
+			linkEntry = &result.Dirs[i]
+		}
+	}
+	if linkEntry == nil {
+		t.Fatalf("symlinked directory %q missing from Dirs; FollowSymlinks did not recurse into it", link)
+	}
+	if linkEntry.Size == 0 {
+		t.Fatalf("symlinked directory %q has size 0, expected file.txt's size", link)
+	}
+
+	result, err = Scan(root, ScanOptions{Workers: 2, TopFiles: 10, FollowSymlinks: false})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	for _, d := range result.Dirs {
+		if d.Path == link {
+			t.Fatalf("symlinked directory %q was scanned with FollowSymlinks disabled", link)
+		}
+	}
+}
+
+func TestScanStopsAtSymlinkCycle(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	loop := filepath.Join(sub, "loop")
+	if err := os.Symlink(root, loop); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	done := make(chan struct{})
+	var result *ScanResult
+	var err error
+	go func() {
+		result, err = Scan(root, ScanOptions{Workers: 2, TopFiles: 10, FollowSymlinks: true})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Scan did not terminate; a symlink pointing back at an ancestor caused infinite recursion")
+	}
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	for _, d := range result.Dirs {
+		if d.Path == loop {
+			t.Fatalf("symlink %q back to its own ancestor %q was recursed into instead of being skipped", loop, root)
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	root := buildTree(b, 4, 6)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Scan(root, ScanOptions{Workers: 8, TopFiles: 10}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDoubleWalk mirrors the old findLargestDirectories/
+// findLargestFiles approach: two separate filepath.Walk passes over the
+// same tree, each paying a Stat per entry. It exists to compare against
+// BenchmarkScan's single pass over os.ReadDir results.
+func BenchmarkDoubleWalk(b *testing.B) {
+	root := buildTree(b, 4, 6)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var dirBytes, fileBytes int64
+		filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				dirBytes++
+			}
+			return nil
+		})
+		filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !info.IsDir() {
+				fileBytes += info.Size()
+			}
+			return nil
+		})
+		_ = dirBytes
+		_ = fileBytes
+	}
+}
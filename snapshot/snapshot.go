@@ -0,0 +1,309 @@
+// Package snapshot keeps a rolling, gzip-compressed history of timestamped
+// disk-usage snapshots, so growth can be measured over multiple intervals
+// (1 day, 7 days, 30 days, ...) instead of only "since the last run".
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Entry is a path and its size.
+type Entry struct {
+	Path string
+	Size int64
+}
+
+// Store persists timestamped snapshots of Entry slices under Dir, one
+// file per (kind, timestamp), and prunes them according to Retain and
+// RetainCount.
+type Store struct {
+	Dir string
+
+	// Retain removes snapshots older than this. Zero disables time-based
+	// retention.
+	Retain time.Duration
+	// RetainCount keeps at most this many of the newest snapshots per
+	// kind, on top of Retain. Zero disables count-based retention.
+	RetainCount int
+}
+
+// NewStore returns a Store rooted at dir.
+func NewStore(dir string, retain time.Duration, retainCount int) *Store {
+	return &Store{Dir: dir, Retain: retain, RetainCount: retainCount}
+}
+
+const filenameLayout = "2006-01-02T15:04:05Z"
+
+var snapPattern = regexp.MustCompile(`^snap-(.+)\.([a-z]+)$`)
+
+func (s *Store) filename(kind string, t time.Time) string {
+	return filepath.Join(s.Dir, fmt.Sprintf("snap-%s.%s", t.UTC().Format(filenameLayout), kind))
+}
+
+// Save writes entries as a new snapshot for kind ("dirs" or "files")
+// timestamped at t, gzip-compressed with each entry length-prefixed.
+func (s *Store) Save(kind string, t time.Time, entries []Entry) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(s.filename(kind, t))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	w := bufio.NewWriter(gw)
+	for _, e := range entries {
+		if err := writeEntry(w, e); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// Load reads back a snapshot file written by Save.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	return readEntries(gr)
+}
+
+func writeEntry(w io.Writer, e Entry) error {
+	if err := binary.Write(w, binary.LittleEndian, e.Size); err != nil {
+		return err
+	}
+	path := []byte(e.Path)
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(path))); err != nil {
+		return err
+	}
+	_, err := w.Write(path)
+	return err
+}
+
+func readEntries(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	for {
+		var size int64
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		path := make([]byte, n)
+		if _, err := io.ReadFull(r, path); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Path: string(path), Size: size})
+	}
+	return entries, nil
+}
+
+// times returns the timestamps of every stored snapshot for kind, oldest
+// first.
+func (s *Store) times(kind string) ([]time.Time, error) {
+	files, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var times []time.Time
+	for _, f := range files {
+		m := snapPattern.FindStringSubmatch(f.Name())
+		if m == nil || m[2] != kind {
+			continue
+		}
+		t, err := time.Parse(filenameLayout, m[1])
+		if err != nil {
+			continue
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// nearestAtLeast returns the most recent of times (sorted oldest first)
+// that is at least age older than now.
+func nearestAtLeast(times []time.Time, now time.Time, age time.Duration) (time.Time, bool) {
+	cutoff := now.Add(-age)
+	var best time.Time
+	found := false
+	for _, t := range times {
+		if !t.After(cutoff) {
+			best = t
+			found = true
+		}
+	}
+	return best, found
+}
+
+// GrowthOver returns the growth in entries for kind since the nearest
+// snapshot at least age old, sorted by largest growth first and capped at
+// maxItems. It returns (nil, nil) if no snapshot is old enough yet or no
+// current snapshot exists.
+func (s *Store) GrowthOver(kind string, now time.Time, age time.Duration, maxItems int) ([]Entry, error) {
+	times, err := s.times(kind)
+	if err != nil || len(times) == 0 {
+		return nil, err
+	}
+
+	baseline, ok := nearestAtLeast(times, now, age)
+	if !ok {
+		return nil, nil
+	}
+
+	current, err := Load(s.filename(kind, times[len(times)-1]))
+	if err != nil {
+		return nil, err
+	}
+	previous, err := Load(s.filename(kind, baseline))
+	if err != nil {
+		return nil, err
+	}
+
+	prevSize := make(map[string]int64, len(previous))
+	for _, e := range previous {
+		prevSize[e.Path] = e.Size
+	}
+
+	var growth []Entry
+	for _, e := range current {
+		if p, ok := prevSize[e.Path]; ok && e.Size > p {
+			growth = append(growth, Entry{Path: e.Path, Size: e.Size - p})
+		}
+	}
+
+	sort.Slice(growth, func(i, j int) bool { return growth[i].Size > growth[j].Size })
+	if len(growth) > maxItems {
+		growth = growth[:maxItems]
+	}
+	return growth, nil
+}
+
+// GC removes snapshots for kind that exceed the retention policy: first
+// anything older than Retain, then (if RetainCount is also set) the
+// oldest of whatever survived beyond RetainCount.
+func (s *Store) GC(kind string, now time.Time) (int, error) {
+	times, err := s.times(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	var keep []time.Time
+	removed := 0
+	for _, t := range times {
+		if s.Retain > 0 && now.Sub(t) > s.Retain {
+			if err := os.Remove(s.filename(kind, t)); err != nil {
+				return removed, err
+			}
+			removed++
+			continue
+		}
+		keep = append(keep, t)
+	}
+
+	if s.RetainCount > 0 && len(keep) > s.RetainCount {
+		excess := keep[:len(keep)-s.RetainCount]
+		for _, t := range excess {
+			if err := os.Remove(s.filename(kind, t)); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// GrowthRate fits a least-squares line to path's size across the
+// snapshots for kind taken within window (ending at now) and returns the
+// slope in bytes/day. ok is false if fewer than two samples of path
+// exist in the window.
+func (s *Store) GrowthRate(kind, path string, now time.Time, window time.Duration) (bytesPerDay float64, ok bool) {
+	times, err := s.times(kind)
+	if err != nil {
+		return 0, false
+	}
+
+	cutoff := now.Add(-window)
+	var xs, ys []float64
+	for _, t := range times {
+		if t.Before(cutoff) {
+			continue
+		}
+		entries, err := Load(s.filename(kind, t))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.Path == path {
+				xs = append(xs, now.Sub(t).Hours()/24)
+				ys = append(ys, float64(e.Size))
+				break
+			}
+		}
+	}
+
+	if len(xs) < 2 {
+		return 0, false
+	}
+	// xs holds "days before now"; negate the fitted slope so a growing
+	// path (larger size at smaller x-before-now) yields a positive rate.
+	slope, _ := linearRegression(xs, ys)
+	return -slope, true
+}
+
+// linearRegression returns the least-squares slope and intercept of the
+// line through (xs[i], ys[i]).
+func linearRegression(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept
+}
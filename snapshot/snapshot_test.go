@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 0, 0)
+
+	now := time.Date(2026, 1, 15, 2, 0, 0, 0, time.UTC)
+	want := []Entry{{Path: "/var/log/foo", Size: 100}, {Path: "/var/log/bar", Size: 42}}
+	if err := s.Save("dirs", now, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := Load(s.filename("dirs", now))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGrowthOverPicksNearestEligibleSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 0, 0)
+
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	mustSave(t, s, "dirs", now.Add(-30*24*time.Hour), []Entry{{Path: "/var/log/foo", Size: 10}})
+	mustSave(t, s, "dirs", now.Add(-1*24*time.Hour), []Entry{{Path: "/var/log/foo", Size: 50}})
+	mustSave(t, s, "dirs", now, []Entry{{Path: "/var/log/foo", Size: 80}})
+
+	growth, err := s.GrowthOver("dirs", now, 24*time.Hour, 5)
+	if err != nil {
+		t.Fatalf("GrowthOver: %v", err)
+	}
+	if len(growth) != 1 || growth[0].Path != "/var/log/foo" || growth[0].Size != 30 {
+		t.Fatalf("growth = %+v, want [{/var/log/foo 30}]", growth)
+	}
+}
+
+func TestGCRemovesSnapshotsBeyondRetention(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir, 7*24*time.Hour, 0)
+
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	mustSave(t, s, "dirs", now.Add(-30*24*time.Hour), []Entry{{Path: "/a", Size: 1}})
+	mustSave(t, s, "dirs", now, []Entry{{Path: "/a", Size: 2}})
+
+	removed, err := s.GC("dirs", now)
+	if err != nil {
+		t.Fatalf("GC: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+
+	times, err := s.times("dirs")
+	if err != nil {
+		t.Fatalf("times: %v", err)
+	}
+	if len(times) != 1 {
+		t.Fatalf("remaining snapshots = %d, want 1", len(times))
+	}
+}
+
+func mustSave(t *testing.T, s *Store, kind string, when time.Time, entries []Entry) {
+	t.Helper()
+	if err := s.Save(kind, when, entries); err != nil {
+		t.Fatalf("Save(%s, %s): %v", kind, when, err)
+	}
+}